@@ -13,6 +13,16 @@ import {
 
 import type { Env } from "@/env"
 
+/**
+ * OAuth client for one sign-in provider, or undefined (provider off)
+ * unless both halves of the credentials are set. Callbacks land on
+ * `<API_BASE_URL>/auth/callback/<provider>`.
+ */
+const oauthClient = (clientId?: string, clientSecret?: string) =>
+  clientId !== undefined && clientSecret !== undefined
+    ? { clientId, clientSecret }
+    : undefined
+
 /**
  * Configure better-auth with the Drizzle adapter and the StellarStack-
  * specific user fields. We don't use better-auth's `jwt()` plugin — the
@@ -48,6 +58,20 @@ export const buildAuth = (params: { db: Db; env: Env }) => {
       },
     },
     emailAndPassword: { enabled: true, autoSignIn: true },
+    socialProviders: {
+      discord: oauthClient(
+        params.env.DISCORD_CLIENT_ID,
+        params.env.DISCORD_CLIENT_SECRET
+      ),
+      google: oauthClient(
+        params.env.GOOGLE_CLIENT_ID,
+        params.env.GOOGLE_CLIENT_SECRET
+      ),
+      github: oauthClient(
+        params.env.GITHUB_CLIENT_ID,
+        params.env.GITHUB_CLIENT_SECRET
+      ),
+    },
     databaseHooks: {
       user: {
         create: {
@@ -21,3 +21,16 @@ export const errorToResponse = (
   console.error("api error:", err)
   return c.json({ error: { code: "internal.unexpected" } }, 500)
 }
+
+/**
+ * Whether `err` is Postgres rejecting a write on a unique constraint
+ * (SQLSTATE 23505). drizzle wraps driver errors, so the cause counts too.
+ */
+export const isUniqueViolation = (err: unknown): boolean => {
+  const code = (e: unknown) =>
+    typeof e === "object" && e !== null && "code" in e ? e.code : undefined
+  return (
+    code(err) === "23505" ||
+    (err instanceof Error && code(err.cause) === "23505")
+  )
+}
@@ -13,6 +13,7 @@ import { errorToResponse } from "@/lib/Errors"
 import { InstallRunner } from "@/lib/InstallRunner"
 import { Scheduler } from "@/lib/Scheduler"
 import { StatusCache } from "@/lib/StatusCache"
+import { metricsMiddleware, renderMetrics } from "@/middleware/Metrics"
 import { requestIdMiddleware, type ApiVariables } from "@/middleware/RequestId"
 import { buildActivityRoute } from "@/routes/Activity"
 import { buildAdminAuditRoute } from "@/routes/AdminAudit"
@@ -59,6 +60,7 @@ app.use("*", cors({
   exposeHeaders: ["X-Request-Id"],
 }))
 app.use("*", requestIdMiddleware)
+app.use("*", metricsMiddleware)
 
 app.onError((err, c) => {
   if (!(err instanceof ApiException)) {
@@ -67,6 +69,14 @@ app.onError((err, c) => {
   return errorToResponse(c, err)
 })
 
+// Prometheus scrape. Caddy only routes /api and /auth here, so this is
+// reachable from the stack's network alone.
+app.get("/metrics", (c) =>
+  c.text(renderMetrics(), 200, {
+    "Content-Type": "text/plain; version=0.0.4; charset=utf-8",
+  })
+)
+
 app.on(["GET", "POST", "PUT", "DELETE"], "/auth/*", (c) =>
   auth.handler(c.req.raw)
 )
@@ -0,0 +1,76 @@
+import { createMiddleware } from "hono/factory"
+
+type Counter = { count: number; seconds: number }
+
+const requests = new Map<string, number>()
+const durations = new Map<string, Counter>()
+
+/**
+ * Counts every request by method, matched route and status, and sums
+ * handling time per route, for `renderMetrics`. The route is the pattern
+ * (`/api/servers/:id`), never the raw path, so ids don't become labels;
+ * anything no route matched is counted as `unmatched`.
+ */
+export const metricsMiddleware = createMiddleware(async (c, next) => {
+  const started = performance.now()
+  await next()
+  const handler = c.req.matchedRoutes.filter((r) => r.method !== "ALL").at(-1)
+  const route = handler?.path ?? "unmatched"
+  const key = `${c.req.method}\t${route}\t${c.res.status}`
+  requests.set(key, (requests.get(key) ?? 0) + 1)
+  const d = durations.get(route) ?? { count: 0, seconds: 0 }
+  d.count += 1
+  d.seconds += (performance.now() - started) / 1000
+  durations.set(route, d)
+})
+
+// Route patterns and methods are plain ASCII, so JSON's quoting is the
+// exposition format's.
+const label = (value: string) => JSON.stringify(value)
+
+/**
+ * The counters in the Prometheus text format, plus process memory and
+ * uptime. Served on /metrics, which Caddy doesn't route, so only the
+ * stack's own network (Prometheus) can reach it.
+ */
+export const renderMetrics = (): string => {
+  const lines: string[] = []
+  const family = (name: string, type: string, help: string) =>
+    lines.push(`# HELP ${name} ${help}`, `# TYPE ${name} ${type}`)
+
+  family(
+    "stellar_api_http_requests_total",
+    "counter",
+    "HTTP requests handled, by method, route and status."
+  )
+  for (const [key, count] of requests) {
+    const [method = "", route = "", status = ""] = key.split("\t")
+    lines.push(
+      `stellar_api_http_requests_total{method=${label(method)},route=${label(route)},status=${label(status)}} ${count}`
+    )
+  }
+  family(
+    "stellar_api_http_request_duration_seconds",
+    "summary",
+    "Time spent handling requests, by route."
+  )
+  for (const [route, d] of durations) {
+    lines.push(
+      `stellar_api_http_request_duration_seconds_sum{route=${label(route)}} ${d.seconds}`,
+      `stellar_api_http_request_duration_seconds_count{route=${label(route)}} ${d.count}`
+    )
+  }
+  family(
+    "process_resident_memory_bytes",
+    "gauge",
+    "Resident memory size in bytes."
+  )
+  lines.push(`process_resident_memory_bytes ${process.memoryUsage().rss}`)
+  family(
+    "process_uptime_seconds",
+    "gauge",
+    "Seconds since the process started."
+  )
+  lines.push(`process_uptime_seconds ${process.uptime()}`)
+  return `${lines.join("\n")}\n`
+}
@@ -1,6 +1,6 @@
 import { randomBytes, randomUUID, createHash } from "node:crypto"
 
-import { eq } from "drizzle-orm"
+import { and, eq, inArray, isNull } from "drizzle-orm"
 import { Hono } from "hono"
 import { z } from "zod"
 
@@ -16,6 +16,7 @@ import {
 } from "@workspace/shared/errors"
 
 import type { Auth } from "@/auth"
+import { isUniqueViolation } from "@/lib/Errors"
 import type { AuthVariables } from "@/middleware/RequireSession"
 import { buildRequireAdmin } from "@/middleware/RequireAdmin"
 
@@ -46,6 +47,11 @@ const allocationsSchema = z
     "ports or portRange required"
   )
 
+const readdressSchema = z.object({
+  from: z.string().min(1),
+  to: z.string().min(1),
+})
+
 const PAIRING_TTL_SECONDS = 600
 
 /**
@@ -165,6 +171,49 @@ export const buildNodesRoute = (params: { auth: Auth; db: Db }) => {
         .returning()
       return c.json({ created: inserted.length, allocations: inserted })
     })
+    // Move every allocation on `from` to `to`, e.g. after the node's
+    // public IP changed. Assigned allocations keep their servers; free
+    // ones already on `to` for the same ports are dropped instead of
+    // colliding with the moved rows.
+    .patch("/:id/allocations", async (c) => {
+      const id = c.req.param("id")
+      const parsed = readdressSchema.safeParse(await c.req.json())
+      if (!parsed.success) throw apiValidationError(parsed.error)
+      const { from, to } = parsed.data
+      const onNode = (ip: string) =>
+        and(
+          eq(nodeAllocationsTable.nodeId, id),
+          eq(nodeAllocationsTable.ip, ip)
+        )
+      try {
+        const updated = await db.transaction(async (tx) => {
+          const moving = await tx
+            .select({ port: nodeAllocationsTable.port })
+            .from(nodeAllocationsTable)
+            .where(onNode(from))
+          if (moving.length === 0) return []
+          await tx.delete(nodeAllocationsTable).where(
+            and(
+              onNode(to),
+              isNull(nodeAllocationsTable.serverId),
+              inArray(nodeAllocationsTable.port, moving.map((m) => m.port))
+            )
+          )
+          return tx
+            .update(nodeAllocationsTable)
+            .set({ ip: to })
+            .where(onNode(from))
+            .returning()
+        })
+        return c.json({ updated: updated.length })
+      } catch (err) {
+        // A port on `to` that's already assigned to a server.
+        if (isUniqueViolation(err)) {
+          throw new ApiException("nodes.allocations.conflict", { status: 409 })
+        }
+        throw err
+      }
+    })
     .delete("/:id/allocations/:allocId", async (c) => {
       const allocId = c.req.param("allocId")
       const row = (
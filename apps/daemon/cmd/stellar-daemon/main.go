@@ -16,6 +16,7 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
@@ -31,7 +32,19 @@ import (
 	"github.com/stellarstack/daemon/internal/sftp"
 )
 
+// Set at build time with -ldflags "-X main.version=… -X main.commit=…".
+var (
+	version = "dev"
+	commit  = "unknown"
+)
+
 func main() {
+	// Release builds only link main.version; the packages report config.Version.
+	config.Version = version
+	if len(os.Args) > 1 && os.Args[1] == "version" {
+		fmt.Printf("stellar-daemon %s (%s)\n", version, commit)
+		return
+	}
 	if len(os.Args) > 1 && os.Args[1] == "configure" {
 		if err := runConfigure(os.Args[2:]); err != nil {
 			fmt.Fprintln(os.Stderr, "configure:", err)
@@ -72,8 +85,9 @@ func main() {
 		}
 	}()
 
-	mgr := server.NewManager(dc, panelClient, cfg.HistoryLines)
-	fm := files.New(cfg.DataDir)
+	owner := files.Owner{UID: cfg.FileUID, GID: cfg.FileGID}
+	mgr := server.NewManager(dc, panelClient, cfg.HistoryLines, owner)
+	fm := files.New(cfg.DataDir, owner)
 	bm := backup.New(cfg.DataDir)
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -100,12 +114,14 @@ func main() {
 		Verifier    *stellarjwt.Verifier
 		DataDir     string
 		NodeID      string
+		Owner       files.Owner
 	}{
 		Listen:      cfg.SFTPListen,
 		HostKeyPath: cfg.SFTPHostKey,
 		Verifier:    verifier,
 		DataDir:     cfg.DataDir,
 		NodeID:      cfg.NodeID,
+		Owner:       owner,
 	})
 	if err != nil {
 		log.Printf("sftp: skipped (%v)", err)
@@ -146,33 +162,72 @@ func defaultConfigPath() string {
 // signing key and writes a fresh config.toml to disk.
 //
 // Usage: stellar-daemon configure <api-base-url> <pairing-token> [--out PATH]
+// [--force] [--data-dir DIR] [--http-listen ADDR] [--sftp-listen ADDR]
+// [--allocation-ports FIRST-LAST] [--file-uid UID --file-gid GID]
+// [--metrics-token TOKEN]
 //
 // The pairing token format is `<nodeId>.<random>`; the daemon POSTs it
 // to `<api>/api/nodes/pair/exchange`, receives `{nodeId, signingKey}`,
 // and writes a config.toml the next `stellar-daemon` invocation can boot
-// from. Existing config files are preserved unless --force is passed.
+// from. The remaining flags land in the same file, validated before the
+// token is spent. Existing config files are preserved unless --force is
+// passed.
 func runConfigure(args []string) error {
 	if len(args) < 2 {
-		return fmt.Errorf("usage: stellar-daemon configure <api-base-url> <pairing-token> [--out PATH] [--force]")
+		return fmt.Errorf("usage: stellar-daemon configure <api-base-url> <pairing-token> [--out PATH] [--force] [--data-dir DIR] [--http-listen ADDR] [--sftp-listen ADDR] [--allocation-ports FIRST-LAST] [--file-uid UID --file-gid GID] [--metrics-token TOKEN]")
 	}
 	apiBase := strings.TrimRight(args[0], "/")
 	token := args[1]
 	outPath := defaultConfigPath()
 	force := false
+	fileUID, fileGID := "", ""
+	cfg := config.Config{APIBaseURL: apiBase}
+	values := map[string]*string{
+		"--out":              &outPath,
+		"--data-dir":         &cfg.DataDir,
+		"--http-listen":      &cfg.HTTPListen,
+		"--sftp-listen":      &cfg.SFTPListen,
+		"--allocation-ports": &cfg.AllocationPorts,
+		"--file-uid":         &fileUID,
+		"--file-gid":         &fileGID,
+		"--metrics-token":    &cfg.MetricsToken,
+	}
 	for i := 2; i < len(args); i++ {
-		switch args[i] {
-		case "--out":
+		if dst, ok := values[args[i]]; ok {
 			if i+1 >= len(args) {
-				return fmt.Errorf("--out requires a value")
+				return fmt.Errorf("%s requires a value", args[i])
 			}
-			outPath = args[i+1]
+			*dst = args[i+1]
 			i++
+			continue
+		}
+		switch args[i] {
 		case "--force":
 			force = true
 		default:
 			return fmt.Errorf("unknown flag %q", args[i])
 		}
 	}
+	for _, id := range []struct {
+		flag, value string
+		dst         *int
+	}{{"--file-uid", fileUID, &cfg.FileUID}, {"--file-gid", fileGID, &cfg.FileGID}} {
+		if id.value == "" {
+			continue
+		}
+		n, err := strconv.Atoi(id.value)
+		if err != nil {
+			return fmt.Errorf("%s %q must be a number", id.flag, id.value)
+		}
+		*id.dst = n
+	}
+	// Check everything but the pair-exchange results up front so a typo
+	// doesn't burn the one-time token.
+	probe := cfg
+	probe.NodeID, probe.SigningKeyHex = "pending", "pending"
+	if err := probe.Validate(); err != nil {
+		return err
+	}
 	if !force {
 		if _, err := os.Stat(outPath); err == nil {
 			return fmt.Errorf("%s exists; pass --force to overwrite", outPath)
@@ -205,21 +260,10 @@ func runConfigure(args []string) error {
 		return err
 	}
 
-	contents := fmt.Sprintf(`# Generated by stellar-daemon configure
-node_id = %q
-signing_key = %q
-api_base_url = %q
-http_listen = ":8081"
-data_dir = "/var/lib/stellarstack"
-docker_socket = "/var/run/docker.sock"
-history_lines = 150
-`, out.NodeID, out.SigningKey, apiBase)
-
-	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
-		return fmt.Errorf("mkdir %s: %w", filepath.Dir(outPath), err)
-	}
-	if err := os.WriteFile(outPath, []byte(contents), 0o600); err != nil {
-		return fmt.Errorf("write %s: %w", outPath, err)
+	cfg.NodeID = out.NodeID
+	cfg.SigningKeyHex = out.SigningKey
+	if err := config.Write(outPath, &cfg); err != nil {
+		return err
 	}
 	fmt.Printf("configured node %s, wrote %s\n", out.NodeID, outPath)
 	return nil
@@ -7,7 +7,12 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/pelletier/go-toml/v2"
 )
@@ -27,6 +32,18 @@ type Config struct {
 	DataDir       string `toml:"data_dir"`
 	DockerSocket  string `toml:"docker_socket"`
 	HistoryLines  int    `toml:"history_lines"`
+	// AllocationPorts is the "first-last" port range the installer
+	// reserved (and opened in the firewall) for game server allocations.
+	AllocationPorts string `toml:"allocation_ports,omitempty"`
+	// FileUID/FileGID own every file in a server's tree, and game
+	// containers run as them, so SFTP uploads stay writable to the
+	// server. Both zero (the default) leaves files root-owned and
+	// containers on their image's user.
+	FileUID int `toml:"file_uid,omitempty"`
+	FileGID int `toml:"file_gid,omitempty"`
+	// MetricsToken is the bearer token Prometheus presents on /metrics.
+	// Empty (the default) leaves the endpoint off.
+	MetricsToken string `toml:"metrics_token,omitempty"`
 }
 
 // Load reads the TOML at `path` and validates the required fields. The
@@ -42,14 +59,25 @@ func Load(path string) (*Config, error) {
 	if err := toml.Unmarshal(raw, &c); err != nil {
 		return nil, fmt.Errorf("parse %s: %w", path, err)
 	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the required fields, fills defaults for the optional
+// ones, and rejects values the daemon would only trip over at runtime
+// (unparseable listen addresses, a relative data dir, a bad port range,
+// half a file owner).
+func (c *Config) Validate() error {
 	if c.NodeID == "" {
-		return nil, errors.New("config: node_id is required (run `stellar-daemon configure <token>`)")
+		return errors.New("config: node_id is required (run `stellar-daemon configure <token>`)")
 	}
 	if c.SigningKeyHex == "" {
-		return nil, errors.New("config: signing_key is required (run `stellar-daemon configure <token>`)")
+		return errors.New("config: signing_key is required (run `stellar-daemon configure <token>`)")
 	}
 	if c.APIBaseURL == "" {
-		return nil, errors.New("config: api_base_url is required")
+		return errors.New("config: api_base_url is required")
 	}
 	if c.HTTPListen == "" {
 		c.HTTPListen = ":8081"
@@ -69,5 +97,91 @@ func Load(path string) (*Config, error) {
 	if c.HistoryLines <= 0 {
 		c.HistoryLines = 150
 	}
-	return &c, nil
+	if u, err := url.Parse(c.APIBaseURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("config: api_base_url %q must be an http(s) URL", c.APIBaseURL)
+	}
+	if err := validListen("http_listen", c.HTTPListen); err != nil {
+		return err
+	}
+	if err := validListen("sftp_listen", c.SFTPListen); err != nil {
+		return err
+	}
+	if !filepath.IsAbs(c.DataDir) {
+		return fmt.Errorf("config: data_dir %q must be an absolute path", c.DataDir)
+	}
+	if c.AllocationPorts != "" {
+		first, last, ok := strings.Cut(c.AllocationPorts, "-")
+		if !ok || !validPort(first) || !validPort(last) || atoi(first) > atoi(last) {
+			return fmt.Errorf("config: allocation_ports %q must look like 25565-25600", c.AllocationPorts)
+		}
+	}
+	if c.FileUID < 0 || c.FileGID < 0 {
+		return fmt.Errorf("config: file_uid/file_gid (%d/%d) can't be negative", c.FileUID, c.FileGID)
+	}
+	if (c.FileUID == 0) != (c.FileGID == 0) {
+		return fmt.Errorf("config: set both file_uid and file_gid, or neither (got %d/%d)", c.FileUID, c.FileGID)
+	}
+	return nil
+}
+
+// Write validates `c` and replaces the file at `path` with it. The new
+// contents go to a temp file in the same directory first so a crash
+// mid-write never leaves the daemon with a truncated config.
+func Write(path string, c *Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	body, err := toml.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+	tmp, err := os.CreateTemp(dir, ".config-*.toml")
+	if err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString("# Generated by stellar-daemon configure\n")
+	if err == nil {
+		_, err = tmp.Write(body)
+	}
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod %s: %w", path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+func validListen(key, addr string) error {
+	if _, port, err := net.SplitHostPort(addr); err != nil || !validPort(port) {
+		return fmt.Errorf("config: %s %q must be host:port", key, addr)
+	}
+	return nil
+}
+
+func validPort(s string) bool {
+	n := atoi(s)
+	return n >= 1 && n <= 65535
+}
+
+// atoi returns -1 for anything that isn't a plain decimal number.
+func atoi(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
 }
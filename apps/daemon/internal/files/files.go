@@ -22,9 +22,13 @@ import (
 // `<dataDir>/servers/<uuid>`.
 type Manager struct {
 	dataDir string
+	owner   Owner
 }
 
-func New(dataDir string) *Manager { return &Manager{dataDir: dataDir} }
+func New(dataDir string, owner Owner) *Manager { return &Manager{dataDir: dataDir, owner: owner} }
+
+// Owner is who files written into server trees belong to.
+func (m *Manager) Owner() Owner { return m.owner }
 
 // Entry is one filesystem entry returned by List.
 type Entry struct {
@@ -116,7 +120,7 @@ func (m *Manager) Write(serverID, path string, body io.Reader) error {
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+	if err := m.owner.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
 		return err
 	}
 	f, err := os.Create(abs)
@@ -127,7 +131,7 @@ func (m *Manager) Write(serverID, path string, body io.Reader) error {
 	if _, err := io.Copy(f, body); err != nil {
 		return err
 	}
-	return nil
+	return m.owner.Chown(abs)
 }
 
 // Mkdir creates a directory (recursive, idempotent).
@@ -136,7 +140,7 @@ func (m *Manager) Mkdir(serverID, path string) error {
 	if err != nil {
 		return err
 	}
-	return os.MkdirAll(abs, 0o755)
+	return m.owner.MkdirAll(abs, 0o755)
 }
 
 // Delete removes a file or directory tree.
@@ -161,7 +165,7 @@ func (m *Manager) Move(serverID, from, to string) error {
 	if err != nil {
 		return err
 	}
-	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+	if err := m.owner.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
 		return err
 	}
 	return os.Rename(src, dst)
@@ -192,8 +196,16 @@ func (m *Manager) Stat(serverID, path string) (Entry, error) {
 // `.tar` → tar, `.zip` → zip, `.gz` → single-file gzip. Every entry
 // is jail-checked against `destDir` (no `..` or absolute escapes) and
 // symlinks/devices are skipped, mirroring the upstream daemon's jail
-// rules.
+// rules. The extracted tree is handed to the configured Owner.
 func (m *Manager) Decompress(serverID, archivePath, destDir string) error {
+	if err := m.decompress(serverID, archivePath, destDir); err != nil {
+		return err
+	}
+	dst, _ := m.resolve(serverID, destDir)
+	return m.owner.ChownTree(dst)
+}
+
+func (m *Manager) decompress(serverID, archivePath, destDir string) error {
 	src, err := m.resolve(serverID, archivePath)
 	if err != nil {
 		return err
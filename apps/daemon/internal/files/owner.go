@@ -0,0 +1,74 @@
+package files
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// Owner is the uid/gid game server files belong to (`file_uid` /
+// `file_gid` in config.toml). Game containers run as it, so anything the
+// daemon writes into a server tree — uploads, extracted archives,
+// install output — is handed to it too. The zero Owner leaves ownership
+// alone: files stay with whoever wrote them (root, for the daemon).
+type Owner struct {
+	UID int
+	GID int
+}
+
+// IsZero reports whether ownership is left alone.
+func (o Owner) IsZero() bool { return o.UID == 0 && o.GID == 0 }
+
+// User is the "uid:gid" docker runs a container as, or "" to keep the
+// image's own user.
+func (o Owner) User() string {
+	if o.IsZero() {
+		return ""
+	}
+	return strconv.Itoa(o.UID) + ":" + strconv.Itoa(o.GID)
+}
+
+// Chown gives `path` (not what a symlink points at) to the owner.
+func (o Owner) Chown(path string) error {
+	if o.IsZero() {
+		return nil
+	}
+	return os.Lchown(path, o.UID, o.GID)
+}
+
+// ChownTree gives `root` and everything under it to the owner.
+func (o Owner) ChownTree(root string) error {
+	if o.IsZero() {
+		return nil
+	}
+	return filepath.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, o.UID, o.GID)
+	})
+}
+
+// MkdirAll is os.MkdirAll that chowns each directory it creates, so a
+// nested upload doesn't leave root-owned parents behind.
+func (o Owner) MkdirAll(path string, perm os.FileMode) error {
+	if st, err := os.Stat(path); err == nil {
+		if !st.IsDir() {
+			return &os.PathError{Op: "mkdir", Path: path, Err: os.ErrExist}
+		}
+		return nil
+	}
+	if parent := filepath.Dir(path); parent != path {
+		if err := o.MkdirAll(parent, perm); err != nil {
+			return err
+		}
+	}
+	if err := os.Mkdir(path, perm); err != nil {
+		if os.IsExist(err) {
+			return nil
+		}
+		return err
+	}
+	return o.Chown(path)
+}
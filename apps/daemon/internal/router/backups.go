@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"path/filepath"
 )
 
 // handleBackups is invoked by the API (HMAC-authenticated, not browser
@@ -48,6 +49,11 @@ func (r *Router) handleBackups(w http.ResponseWriter, req *http.Request, serverI
 			writeJSONError(w, http.StatusInternalServerError, "backups.restore_failed")
 			return
 		}
+		if err := r.files.Owner().ChownTree(filepath.Join(r.cfg.DataDir, "servers", serverID)); err != nil {
+			srv.PublishDaemon("Restore failed: " + err.Error())
+			writeJSONError(w, http.StatusInternalServerError, "backups.restore_failed")
+			return
+		}
 		srv.PublishDaemon("Restore of '" + body.Name + "' complete")
 		writeJSON(w, map[string]any{"ok": true})
 	case "delete":
@@ -144,6 +144,11 @@ func (r *Router) handleInstall(w http.ResponseWriter, req *http.Request, serverU
 	if st != nil {
 		exitCode = st.ExitCode
 	}
+	// Install scripts run as root; hand what they wrote to the file
+	// owner the game container runs as.
+	if err := r.files.Owner().ChownTree(serverDir); err != nil {
+		emit(w, flusher, "stderr", "chown server dir: "+err.Error())
+	}
 	finalize(w, flusher, exitCode)
 }
 
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"crypto/hmac"
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/stellarstack/daemon/internal/config"
+	"github.com/stellarstack/daemon/internal/docker"
+	"github.com/stellarstack/daemon/internal/environment"
+)
+
+// handleMetrics serves /metrics in the Prometheus text format: servers by
+// state, the newest resource sample of every running server, and a few
+// runtime gauges. Written by hand rather than through client_golang for
+// the same reason the router avoids a routing library.
+//
+// The daemon's port is public, so the endpoint only exists when the
+// config carries a metrics_token, and Prometheus must present it.
+func (r *Router) handleMetrics(w http.ResponseWriter, req *http.Request) {
+	if r.cfg.MetricsToken == "" {
+		http.NotFound(w, req)
+		return
+	}
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !ok || !hmac.Equal([]byte(token), []byte(r.cfg.MetricsToken)) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	servers := r.manager.All()
+	sort.Slice(servers, func(i, j int) bool { return servers[i].UUID() < servers[j].UUID() })
+	byState := map[environment.State]int{}
+	for _, s := range servers {
+		byState[s.Environment().State()]++
+	}
+
+	var b strings.Builder
+	metric := func(name, kind, help string) {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+
+	metric("stellar_daemon_build_info", "gauge", "Daemon version, always 1.")
+	fmt.Fprintf(&b, "stellar_daemon_build_info{version=%q} 1\n", config.Version)
+
+	metric("stellar_daemon_servers", "gauge", "Servers on this node by lifecycle state.")
+	for _, state := range []environment.State{
+		environment.StateOffline, environment.StateStarting,
+		environment.StateRunning, environment.StateStopping,
+	} {
+		fmt.Fprintf(&b, "stellar_daemon_servers{state=%q} %d\n", state, byState[state])
+	}
+
+	// Each family is written whole, as the format requires, over the
+	// servers that have a sample.
+	samples := make(map[string]docker.StatsSnapshot, len(servers))
+	for _, s := range servers {
+		if snap, ok := s.Stats(); ok {
+			samples[s.UUID()] = snap
+		}
+	}
+	for _, f := range []struct {
+		name, kind, help string
+		value            func(docker.StatsSnapshot) float64
+	}{
+		{"stellar_server_cpu_percent", "gauge", "CPU use in percent of one core.",
+			func(s docker.StatsSnapshot) float64 { return s.CPUAbsolute }},
+		{"stellar_server_memory_bytes", "gauge", "Memory in use, page cache excluded.",
+			func(s docker.StatsSnapshot) float64 { return float64(s.MemoryBytes) }},
+		{"stellar_server_memory_limit_bytes", "gauge", "Memory limit of the server's container.",
+			func(s docker.StatsSnapshot) float64 { return float64(s.MemoryLimitBytes) }},
+		{"stellar_server_network_receive_bytes_total", "counter", "Bytes received since the container started.",
+			func(s docker.StatsSnapshot) float64 { return float64(s.NetworkRxBytes) }},
+		{"stellar_server_network_transmit_bytes_total", "counter", "Bytes sent since the container started.",
+			func(s docker.StatsSnapshot) float64 { return float64(s.NetworkTxBytes) }},
+		{"stellar_server_disk_read_bytes_total", "counter", "Bytes read from disk since the container started.",
+			func(s docker.StatsSnapshot) float64 { return float64(s.DiskReadBytes) }},
+		{"stellar_server_disk_write_bytes_total", "counter", "Bytes written to disk since the container started.",
+			func(s docker.StatsSnapshot) float64 { return float64(s.DiskWriteBytes) }},
+	} {
+		metric(f.name, f.kind, f.help)
+		for _, s := range servers {
+			if snap, ok := samples[s.UUID()]; ok {
+				fmt.Fprintf(&b, "%s{server=%q} %g\n", f.name, s.UUID(), f.value(snap))
+			}
+		}
+	}
+
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	metric("go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	fmt.Fprintf(&b, "go_goroutines %d\n", runtime.NumGoroutine())
+	metric("go_memstats_heap_alloc_bytes", "gauge", "Number of heap bytes allocated and still in use.")
+	fmt.Fprintf(&b, "go_memstats_heap_alloc_bytes %d\n", mem.HeapAlloc)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write([]byte(b.String()))
+}
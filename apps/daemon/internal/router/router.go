@@ -49,6 +49,8 @@ func (r *Router) Handler() http.Handler {
 	mux.HandleFunc("/api/servers/", r.routeServerSubpath)
 	// Remote (API → daemon) control. Path: /api/remote/...
 	mux.HandleFunc("/api/remote/", r.routeRemote)
+	// Prometheus scrape, behind metrics_token.
+	mux.HandleFunc("/metrics", r.handleMetrics)
 	// Health probe.
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		_, _ = w.Write([]byte(`{"ok":true}`))
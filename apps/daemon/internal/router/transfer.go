@@ -83,6 +83,10 @@ func (r *Router) handleTransferIngest(w http.ResponseWriter, req *http.Request,
 			f.Close()
 		}
 	}
+	if err := r.files.Owner().ChownTree(dst); err != nil {
+		writeJSONError(w, http.StatusInternalServerError, "transfer.chown_failed")
+		return
+	}
 	writeJSON(w, map[string]any{"ok": true})
 }
 
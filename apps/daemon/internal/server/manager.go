@@ -8,6 +8,7 @@ import (
 
 	"github.com/stellarstack/daemon/internal/docker"
 	"github.com/stellarstack/daemon/internal/environment"
+	"github.com/stellarstack/daemon/internal/files"
 	"github.com/stellarstack/daemon/internal/panel"
 )
 
@@ -18,16 +19,18 @@ type Manager struct {
 	docker        *docker.Client
 	panel         *panel.Client
 	historyLines  int
+	owner         files.Owner
 
 	mu      sync.RWMutex
 	servers map[string]*Server
 }
 
-func NewManager(d *docker.Client, p *panel.Client, historyLines int) *Manager {
+func NewManager(d *docker.Client, p *panel.Client, historyLines int, owner files.Owner) *Manager {
 	return &Manager{
 		docker:       d,
 		panel:        p,
 		historyLines: historyLines,
+		owner:        owner,
 		servers:      map[string]*Server{},
 	}
 }
@@ -48,7 +51,7 @@ func (m *Manager) Get(uuid string) *Server {
 	if s, ok := m.servers[uuid]; ok {
 		return s
 	}
-	s := New(uuid, m.docker, m.panel, m.historyLines)
+	s := New(uuid, m.docker, m.panel, m.historyLines, m.owner)
 	m.servers[uuid] = s
 	return s
 }
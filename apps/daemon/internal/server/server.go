@@ -17,6 +17,7 @@ import (
 	"github.com/stellarstack/daemon/internal/docker"
 	"github.com/stellarstack/daemon/internal/environment"
 	"github.com/stellarstack/daemon/internal/events"
+	"github.com/stellarstack/daemon/internal/files"
 	"github.com/stellarstack/daemon/internal/panel"
 )
 
@@ -27,6 +28,7 @@ type Server struct {
 	bus     *events.Bus
 	history *consoleHistory
 	panel   *panel.Client
+	owner   files.Owner
 
 	powerLock chan struct{}
 
@@ -38,10 +40,12 @@ type Server struct {
 	attachMu     sync.Mutex
 	attachCancel context.CancelFunc
 
-	// statsCancel + uptime tracking for the WS stats event.
+	// statsCancel + uptime tracking for the WS stats event. lastStats is
+	// the newest sample while running, for /metrics.
 	statsMu     sync.Mutex
 	statsCancel context.CancelFunc
 	startedAt   time.Time
+	lastStats   *docker.StatsSnapshot
 
 	// errorOnce gates one-shot daemon error events (eula-required, …).
 	// Reset on each start so a subsequent run can re-emit. Prevents
@@ -74,7 +78,7 @@ type ConfigFilePatch struct {
 
 // New constructs a Server for the supplied uuid. Container name follows
 // the "stellar-<uuid>" convention so reconcile can find it.
-func New(uuid string, dc *docker.Client, panelClient *panel.Client, historyLines int) *Server {
+func New(uuid string, dc *docker.Client, panelClient *panel.Client, historyLines int, owner files.Owner) *Server {
 	containerName := "stellar-" + uuid
 	env := environment.New(dc, containerName)
 	bus := events.New()
@@ -85,6 +89,7 @@ func New(uuid string, dc *docker.Client, panelClient *panel.Client, historyLines
 		bus:       bus,
 		history:   hist,
 		panel:     panelClient,
+		owner:     owner,
 		powerLock: make(chan struct{}, 1),
 	}
 	env.SetListener(s.onStateChange)
@@ -321,6 +326,12 @@ func (s *Server) doStart(ctx context.Context) error {
 	s.publishDaemon("Updating process configuration files...")
 	if cfg.BindMount != "" {
 		s.applyConfigFiles(cfg.BindMount, cfg.Environment)
+		// The container runs as the owner, so anything left root-owned
+		// (config patches, files from before file_uid was set) would be
+		// unwritable to it.
+		if err := s.owner.ChownTree(cfg.BindMount); err != nil {
+			log.Printf("server %s: chown %s: %v", s.uuid, cfg.BindMount, err)
+		}
 	}
 
 	containerName := s.env.ContainerName()
@@ -354,6 +365,7 @@ func (s *Server) doStart(ctx context.Context) error {
 		CPULimitPercent:  cfg.CPUPercent,
 		PidsLimit:        256,
 		Ports:            cfg.PortMappings,
+		User:             s.owner.User(),
 		OpenStdin:        true,
 		Tty:              true,
 	}); err != nil {
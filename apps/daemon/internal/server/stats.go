@@ -6,6 +6,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/stellarstack/daemon/internal/docker"
 	"github.com/stellarstack/daemon/internal/environment"
 )
 
@@ -29,6 +30,18 @@ func (s *Server) stopStatsPump() {
 		s.statsCancel()
 		s.statsCancel = nil
 	}
+	s.lastStats = nil
+}
+
+// Stats returns the newest resource sample, or false when the server
+// isn't running or no sample has arrived yet.
+func (s *Server) Stats() (docker.StatsSnapshot, bool) {
+	s.statsMu.Lock()
+	defer s.statsMu.Unlock()
+	if s.lastStats == nil {
+		return docker.StatsSnapshot{}, false
+	}
+	return *s.lastStats, true
 }
 
 func (s *Server) runStatsPump(ctx context.Context) {
@@ -47,6 +60,11 @@ func (s *Server) runStatsPump(ctx context.Context) {
 	for snap := range stream {
 		s.statsMu.Lock()
 		started := s.startedAt
+		// A sample still in flight after stopStatsPump mustn't outlive it.
+		if ctx.Err() == nil {
+			sample := snap
+			s.lastStats = &sample
+		}
 		s.statsMu.Unlock()
 		var uptime int64
 		if !started.IsZero() {
@@ -8,15 +8,18 @@ import (
 	"time"
 
 	pkgsftp "github.com/pkg/sftp"
+
+	"github.com/stellarstack/daemon/internal/files"
 )
 
 // chrootFS implements pkg/sftp's Handlers contract against a confined
 // directory tree. Every supplied path is resolved through `resolve`
 // before any os.* call so the SFTP client cannot escape `root` via
-// `..` or absolute paths.
+// `..` or absolute paths. Anything it creates is handed to `owner`.
 type chrootFS struct {
 	root    string
 	resolve func(string) (string, error)
+	owner   files.Owner
 }
 
 func (f *chrootFS) Fileread(req *pkgsftp.Request) (io.ReaderAt, error) {
@@ -32,10 +35,18 @@ func (f *chrootFS) Filewrite(req *pkgsftp.Request) (io.WriterAt, error) {
 	if err != nil {
 		return nil, err
 	}
-	if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+	if err := f.owner.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+		return nil, err
+	}
+	out, err := os.OpenFile(abs, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := f.owner.Chown(abs); err != nil {
+		out.Close()
 		return nil, err
 	}
-	return os.OpenFile(abs, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	return out, nil
 }
 
 func (f *chrootFS) Filecmd(req *pkgsftp.Request) error {
@@ -46,28 +57,32 @@ func (f *chrootFS) Filecmd(req *pkgsftp.Request) error {
 	switch req.Method {
 	case "Setstat":
 		// File attributes (chmod/chown) are intentionally a no-op: the
-		// container runs as a fixed UID, so changing modes from outside
-		// is rarely meaningful and gives a tidy default for clients.
+		// container runs as the fixed file_uid/file_gid every upload is
+		// already owned by, so changing modes from outside is rarely
+		// meaningful and gives a tidy default for clients.
 		return nil
 	case "Rename":
 		target, err := f.resolve(req.Target)
 		if err != nil {
 			return err
 		}
-		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		if err := f.owner.MkdirAll(filepath.Dir(target), 0o755); err != nil {
 			return err
 		}
 		return os.Rename(abs, target)
 	case "Rmdir":
 		return os.Remove(abs)
 	case "Mkdir":
-		return os.MkdirAll(abs, 0o755)
+		return f.owner.MkdirAll(abs, 0o755)
 	case "Symlink":
 		target, err := f.resolve(req.Target)
 		if err != nil {
 			return err
 		}
-		return os.Symlink(target, abs)
+		if err := os.Symlink(target, abs); err != nil {
+			return err
+		}
+		return f.owner.Chown(abs)
 	case "Remove":
 		return os.Remove(abs)
 	}
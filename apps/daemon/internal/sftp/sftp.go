@@ -25,6 +25,7 @@ import (
 	pkgsftp "github.com/pkg/sftp"
 	"golang.org/x/crypto/ssh"
 
+	"github.com/stellarstack/daemon/internal/files"
 	stellarjwt "github.com/stellarstack/daemon/internal/jwt"
 )
 
@@ -36,6 +37,7 @@ type Server struct {
 	verifier  *stellarjwt.Verifier
 	dataDir   string
 	nodeID    string
+	owner     files.Owner
 }
 
 // New configures the SFTP server. If `hostKeyPath` doesn't exist a
@@ -47,6 +49,7 @@ func New(params struct {
 	Verifier    *stellarjwt.Verifier
 	DataDir     string
 	NodeID      string
+	Owner       files.Owner
 }) (*Server, error) {
 	signer, err := loadOrCreateHostKey(params.HostKeyPath)
 	if err != nil {
@@ -58,6 +61,7 @@ func New(params struct {
 		verifier: params.Verifier,
 		dataDir:  params.DataDir,
 		nodeID:   params.NodeID,
+		owner:    params.Owner,
 	}, nil
 }
 
@@ -82,10 +86,20 @@ func (s *Server) ListenAndServe() error {
 	}
 }
 
-// passwordCallback verifies the JWT presented as the password. Returns
-// the parsed claims via Permissions so the session handler doesn't have
-// to reparse them.
+// passwordCallback wraps authenticate and logs rejected attempts with
+// the peer address — the installer's fail2ban filter keys on that line.
 func (s *Server) passwordCallback(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	perms, err := s.authenticate(c, password)
+	if err != nil {
+		log.Printf("sftp: auth failed for %q from %s: %v", c.User(), c.RemoteAddr(), err)
+	}
+	return perms, err
+}
+
+// authenticate verifies the JWT presented as the password. Returns the
+// parsed claims via Permissions so the session handler doesn't have to
+// reparse them.
+func (s *Server) authenticate(c ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
 	parts := strings.SplitN(c.User(), ".", 2)
 	if len(parts) != 2 {
 		return nil, errors.New("invalid username (expected <userId>.<serverId>)")
@@ -140,7 +154,7 @@ func (s *Server) handleConn(c net.Conn, cfg *ssh.ServerConfig) {
 				if req.Type == "subsystem" && len(req.Payload) >= 4 &&
 					string(req.Payload[4:]) == "sftp" {
 					_ = req.Reply(true, nil)
-					if err := serveSFTP(ch, root); err != nil && err != io.EOF {
+					if err := serveSFTP(ch, root, s.owner); err != nil && err != io.EOF {
 						log.Printf("sftp: serve: %v", err)
 					}
 					return
@@ -154,13 +168,13 @@ func (s *Server) handleConn(c net.Conn, cfg *ssh.ServerConfig) {
 // serveSFTP runs pkg/sftp against a Channel, with all paths confined to
 // `root`. The chroot is implemented via a custom Handlers struct so the
 // SFTP layer can never see anything above `root`.
-func serveSFTP(ch ssh.Channel, root string) error {
-	handlers := chrootHandlers(root)
+func serveSFTP(ch ssh.Channel, root string, owner files.Owner) error {
+	handlers := chrootHandlers(root, owner)
 	srv := pkgsftp.NewRequestServer(ch, handlers)
 	return srv.Serve()
 }
 
-func chrootHandlers(root string) pkgsftp.Handlers {
+func chrootHandlers(root string, owner files.Owner) pkgsftp.Handlers {
 	root = filepath.Clean(root)
 	resolve := func(p string) (string, error) {
 		clean := filepath.Clean("/" + p)
@@ -170,7 +184,7 @@ func chrootHandlers(root string) pkgsftp.Handlers {
 		}
 		return abs, nil
 	}
-	fs := &chrootFS{root: root, resolve: resolve}
+	fs := &chrootFS{root: root, resolve: resolve, owner: owner}
 	return pkgsftp.Handlers{
 		FileGet:  fs,
 		FilePut:  fs,
@@ -0,0 +1,240 @@
+# Provisioning API for `install.sh api`: starts installer runs on this
+# host with a submitted answers file and reports on them.
+#
+#   python3 api-server.py <host> <port> <dir> <token> <installer.conf>
+#
+# <dir> holds install.sh (the installer copy to run) and runs/<id>/ with
+# each run's answers, output.log and run.json.
+#
+#   POST /v1/runs             {"mode": "daemon", "answers": {...}, "args": [...]}
+#   GET  /v1/runs             every run, newest first
+#   GET  /v1/runs/<id>        one run
+#   GET  /v1/runs/<id>/log    its output; ?follow=1 streams until it ends
+#   GET  /v1/status           installer.conf of the current install
+#
+# Every request needs "Authorization: Bearer <token>".
+
+import hmac
+import json
+import os
+import re
+import socket
+import subprocess
+import sys
+import threading
+import time
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from urllib.parse import parse_qs, urlparse
+
+HOST, PORT, DIR, TOKEN, CONF = sys.argv[1], int(sys.argv[2]), sys.argv[3], sys.argv[4], sys.argv[5]
+RUNS = os.path.join(DIR, "runs")
+JOURNAL = os.path.join(os.path.dirname(DIR), "journal")
+MODES = ("full", "panel", "daemon", "rollback")
+TITLE = re.compile(rb"^\x1b\[1m(.+)\x1b\[0m$", re.M)
+lock = threading.Lock()
+runs = {}
+
+
+def save(run):
+    with open(os.path.join(RUNS, run["id"], "run.json"), "w", encoding="utf-8") as f:
+        json.dump(run, f)
+
+
+def load():
+    for run_id in os.listdir(RUNS):
+        try:
+            with open(os.path.join(RUNS, run_id, "run.json"), encoding="utf-8") as f:
+                run = json.load(f)
+        except (OSError, ValueError):
+            continue
+        if run["state"] == "running":
+            # The API stopped while it ran; the installer went with it.
+            run["state"] = "interrupted"
+            save(run)
+        runs[run_id] = run
+
+
+def describe(run):
+    """The run plus what its output and the install journal say."""
+    out = dict(run)
+    try:
+        with open(os.path.join(RUNS, run["id"], "output.log"), "rb") as f:
+            titles = TITLE.findall(f.read())
+        out["step"] = titles[-1].decode("utf-8", "replace") if titles else None
+    except OSError:
+        out["step"] = None
+    # Journal runs are named <time>-<installer pid>.
+    out["journal_run"] = None
+    try:
+        with open(JOURNAL, encoding="utf-8") as f:
+            for line in f:
+                name = line.split("\t", 1)[0]
+                if run.get("pid") and name.endswith("-%d" % run["pid"]):
+                    out["journal_run"] = name
+    except OSError:
+        pass
+    return out
+
+
+def start(mode, answers, args):
+    run_id = base = time.strftime("%Y%m%dT%H%M%SZ", time.gmtime())
+    n = 1
+    while run_id in runs:
+        n += 1
+        run_id = "%s-%d" % (base, n)
+    run_dir = os.path.join(RUNS, run_id)
+    os.makedirs(run_dir, mode=0o700)
+    answers_path = os.path.join(run_dir, "answers")
+    fd = os.open(answers_path, os.O_WRONLY | os.O_CREAT | os.O_TRUNC, 0o600)
+    with os.fdopen(fd, "w", encoding="utf-8") as f:
+        for prompt, answer in answers.items():
+            if isinstance(answer, bool):
+                answer = "yes" if answer else "no"
+            elif isinstance(answer, list):
+                answer = ",".join(str(a) for a in answer)
+            f.write("%s\t%s\n" % (prompt, answer))
+    log = open(os.path.join(run_dir, "output.log"), "wb")
+    proc = subprocess.Popen(
+        ["bash", os.path.join(DIR, "install.sh"), mode, *args, "--answers", answers_path],
+        stdin=subprocess.DEVNULL, stdout=log, stderr=subprocess.STDOUT, cwd="/")
+    log.close()
+    if mode == "rollback":
+        event = "rollback"
+    else:
+        event = "update" if os.path.exists(CONF) else "install"
+    run = {"id": run_id, "mode": mode, "event": event, "args": args, "state": "running",
+           "exit_code": None, "pid": proc.pid, "started": time.time(), "finished": None}
+    runs[run_id] = run
+    save(run)
+
+    def wait():
+        code = proc.wait()
+        with lock:
+            run.update(state="succeeded" if code == 0 else "failed", exit_code=code,
+                       finished=time.time())
+            save(run)
+
+    threading.Thread(target=wait, daemon=True).start()
+    return run
+
+
+def bad_request(answers, args):
+    if not isinstance(answers, dict) or not isinstance(args, list):
+        return "answers must be an object and args a list."
+    for key, value in answers.items():
+        if isinstance(value, (dict, type(None))):
+            return "answer for %r must be a string, number, boolean or list." % key
+        if re.search(r"[\t\n]", key + str(value)):
+            return "answers can't contain tabs or newlines."
+    if not all(isinstance(a, str) for a in args):
+        return "args must be strings."
+    return None
+
+
+class Handler(BaseHTTPRequestHandler):
+    protocol_version = "HTTP/1.1"
+
+    def send(self, code, body, kind="application/json"):
+        data = body if isinstance(body, bytes) else (json.dumps(body) + "\n").encode()
+        self.send_response(code)
+        self.send_header("Content-Type", kind)
+        self.send_header("Content-Length", str(len(data)))
+        self.end_headers()
+        self.wfile.write(data)
+
+    def error(self, code, message):
+        self.send(code, {"error": message})
+
+    def authorized(self):
+        given = self.headers.get("Authorization", "")
+        if hmac.compare_digest(given, "Bearer " + TOKEN):
+            return True
+        self.error(401, "Bad or missing bearer token.")
+        return False
+
+    def do_GET(self):
+        if not self.authorized():
+            return
+        url = urlparse(self.path)
+        parts = url.path.strip("/").split("/")
+        if parts == ["v1", "status"]:
+            config = {}
+            try:
+                with open(CONF, encoding="utf-8") as f:
+                    for line in f:
+                        key, sep, value = line.rstrip("\n").partition("=")
+                        if sep and not key.startswith("#"):
+                            config[key] = value.strip('"')
+            except OSError:
+                pass
+            busy = any(r["state"] == "running" for r in runs.values())
+            self.send(200, {"installed": bool(config), "config": config, "busy": busy})
+        elif parts == ["v1", "runs"]:
+            self.send(200, [describe(r) for r in sorted(runs.values(), key=lambda r: r["id"], reverse=True)])
+        elif len(parts) == 3 and parts[:2] == ["v1", "runs"] and parts[2] in runs:
+            self.send(200, describe(runs[parts[2]]))
+        elif len(parts) == 4 and parts[:2] == ["v1", "runs"] and parts[2] in runs and parts[3] == "log":
+            self.log_output(runs[parts[2]], parse_qs(url.query).get("follow", ["0"])[0] == "1")
+        else:
+            self.error(404, "Not found.")
+
+    def log_output(self, run, follow):
+        path = os.path.join(RUNS, run["id"], "output.log")
+        if not follow:
+            with open(path, "rb") as f:
+                self.send(200, f.read(), "text/plain; charset=utf-8")
+            return
+        self.send_response(200)
+        self.send_header("Content-Type", "text/plain; charset=utf-8")
+        self.send_header("Transfer-Encoding", "chunked")
+        self.end_headers()
+        with open(path, "rb") as f:
+            while True:
+                chunk = f.read(65536)
+                if chunk:
+                    self.wfile.write(b"%x\r\n%s\r\n" % (len(chunk), chunk))
+                    self.wfile.flush()
+                elif run["state"] != "running":
+                    break
+                else:
+                    time.sleep(0.5)
+        self.wfile.write(b"0\r\n\r\n")
+
+    def do_POST(self):
+        if not self.authorized():
+            return
+        if urlparse(self.path).path.rstrip("/") != "/v1/runs":
+            self.error(404, "Not found.")
+            return
+        try:
+            body = json.loads(self.rfile.read(int(self.headers.get("Content-Length") or 0)) or b"{}")
+        except ValueError:
+            self.error(400, "Body must be JSON.")
+            return
+        mode, answers, args = body.get("mode"), body.get("answers", {}), body.get("args", [])
+        if mode not in MODES:
+            self.error(400, "mode must be one of: %s." % ", ".join(MODES))
+            return
+        problem = bad_request(answers, args)
+        if problem:
+            self.error(400, problem)
+            return
+        with lock:
+            if any(r["state"] == "running" for r in runs.values()):
+                self.error(409, "A run is already in progress.")
+                return
+            run = start(mode, answers, args)
+        self.send(201, describe(run))
+
+    def log_message(self, *args):
+        pass
+
+
+load()
+class Server(ThreadingHTTPServer):
+    # ThreadingHTTPServer only binds IPv4; install.sh passes IPv6 hosts
+    # without their brackets.
+    address_family = socket.AF_INET6 if ":" in HOST else socket.AF_INET
+
+
+Server((HOST, PORT), Handler).serve_forever()
@@ -0,0 +1,142 @@
+#!/usr/bin/env bash
+# Moves StellarStack's generated secrets between the secrets backend and
+# the env file compose reads them from. install.sh installs this as
+# /usr/local/sbin/stellarstack-secrets; stellarstack-secrets.service runs
+# `render` at boot so `docker compose` works after a reboot.
+#
+#   stellarstack-secrets render          backend → /run/stellarstack/secrets.env
+#   stellarstack-secrets store <file>    merge KEY=value lines into the backend, then render
+#
+# The backend is read from /etc/stellarstack/installer.conf:
+#
+#   SECRETS_BACKEND=vault   KV v2 secret at VAULT_ADDR / VAULT_PATH
+#                           (<mount>/<path>), token in vault-token
+#   SECRETS_BACKEND=sops    secrets.sops.env, encrypted for
+#                           SOPS_AGE_RECIPIENTS, decrypted with age.key
+#
+# Values are stored bare and rendered single-quoted, the way .env holds
+# them, so compose takes them literally.
+
+set -euo pipefail
+
+CONFIG_DIR="${STELLARSTACK_CONFIG_DIR:-/etc/stellarstack}"
+OUT="${STELLARSTACK_SECRETS_OUT:-/run/stellarstack/secrets.env}"
+# Plaintext temp files, removed however the script exits.
+SCRATCH=()
+trap 'rm -f "${SCRATCH[@]}"' EXIT
+
+conf() {
+  sed -n "s/^$1=//p" "$CONFIG_DIR/installer.conf" 2>/dev/null | tail -n1
+}
+
+# STELLARSTACK_SECRETS_BACKEND overrides it while install.sh switches
+# backends, before installer.conf says so.
+backend() {
+  printf '%s\n' "${STELLARSTACK_SECRETS_BACKEND:-$(conf SECRETS_BACKEND)}"
+}
+
+die() {
+  printf 'stellarstack-secrets: %s\n' "$1" >&2
+  exit 1
+}
+
+# Vault KV v2 keeps data under <mount>/data/<path>.
+vault_url() {
+  local path
+  path=$(conf VAULT_PATH)
+  [[ "$path" == */* ]] || die "VAULT_PATH must be <mount>/<path>, not '$path'."
+  printf '%s/v1/%s/data/%s\n' "$(conf VAULT_ADDR)" "${path%%/*}" "${path#*/}"
+}
+
+vault_curl() {
+  curl -sS --max-time 20 -H "X-Vault-Token: $(cat "$CONFIG_DIR/vault-token")" "$@"
+}
+
+# Print the backend's secrets as bare KEY=value lines (nothing when
+# there are none yet).
+fetch() {
+  case "$(backend)" in
+    vault)
+      local body
+      # 404 means the secret hasn't been written yet.
+      body=$(vault_curl -w '\n%{http_code}' "$(vault_url)") || die "Can't reach Vault at $(conf VAULT_ADDR)."
+      case "${body##*$'\n'}" in
+        200) jq -r '.data.data | to_entries[] | "\(.key)=\(.value)"' <<<"${body%$'\n'*}" ;;
+        404) ;;
+        *) die "Vault at $(conf VAULT_ADDR) answered ${body##*$'\n'}; check the token in $CONFIG_DIR/vault-token." ;;
+      esac
+      ;;
+    sops)
+      [[ -f "$CONFIG_DIR/secrets.sops.env" ]] || return 0
+      SOPS_AGE_KEY_FILE="$CONFIG_DIR/age.key" sops --decrypt --input-type dotenv --output-type dotenv \
+        "$CONFIG_DIR/secrets.sops.env" || die "Couldn't decrypt $CONFIG_DIR/secrets.sops.env with $CONFIG_DIR/age.key."
+      ;;
+    *) die "SECRETS_BACKEND isn't vault or sops in $CONFIG_DIR/installer.conf." ;;
+  esac
+}
+
+# Replace the backend's secrets with the KEY=value lines in $1.
+put() {
+  local plain="$1"
+  case "$(backend)" in
+    vault)
+      jq -Rn '{data: [inputs | select(test("^[A-Za-z_][A-Za-z0-9_]*=")) | capture("^(?<key>[^=]+)=(?<value>.*)$")] | from_entries}' \
+        <"$plain" | vault_curl -f -X POST -H "Content-Type: application/json" --data-binary @- "$(vault_url)" >/dev/null \
+        || die "Couldn't write the secrets to Vault at $(conf VAULT_ADDR)."
+      ;;
+    sops)
+      local recipients
+      recipients=$(conf SOPS_AGE_RECIPIENTS)
+      sops --encrypt --age "${recipients// /,}" --input-type dotenv --output-type dotenv "$plain" \
+        >"$CONFIG_DIR/secrets.sops.env.new" || die "sops couldn't encrypt the secrets."
+      chmod 0600 "$CONFIG_DIR/secrets.sops.env.new"
+      mv "$CONFIG_DIR/secrets.sops.env.new" "$CONFIG_DIR/secrets.sops.env"
+      ;;
+  esac
+}
+
+render() {
+  local line tmp
+  install -d -m 0700 "$(dirname "$OUT")"
+  tmp=$(mktemp "$OUT.XXXXXX")
+  SCRATCH+=("$tmp" "$tmp.quoted")
+  fetch >"$tmp"
+  while IFS= read -r line; do
+    [[ "$line" != *"'"* ]] || die "${line%%=*} contains a single quote, which .env can't hold."
+    printf "%s='%s'\n" "${line%%=*}" "${line#*=}"
+  done <"$tmp" >"$tmp.quoted"
+  chmod 0600 "$tmp.quoted"
+  rm -f "$tmp"
+  mv "$tmp.quoted" "$OUT"
+}
+
+# Merge: keys in $1 win over what the backend already holds. Surrounding
+# single quotes (as .env writes them) are dropped.
+store() {
+  local file="$1" plain line key value
+  local -A merged=()
+  [[ -f "$file" ]] || die "No such file: $file"
+  install -d -m 0700 "$(dirname "$OUT")"
+  plain=$(mktemp "$(dirname "$OUT")/store.XXXXXX")
+  SCRATCH+=("$plain")
+  # Fetch first: a backend that can't be read must stop the store, not
+  # look empty and get overwritten.
+  fetch >"$plain"
+  while IFS= read -r line; do
+    [[ "$line" =~ ^([A-Za-z_][A-Za-z0-9_]*)=(.*)$ ]] || continue
+    key="${BASH_REMATCH[1]}" value="${BASH_REMATCH[2]}"
+    value="${value#\'}" value="${value%\'}"
+    merged[$key]="$value"
+  done < <(cat "$plain" "$file")
+  for key in "${!merged[@]}"; do
+    printf '%s=%s\n' "$key" "${merged[$key]}"
+  done | sort >"$plain"
+  put "$plain"
+  render
+}
+
+case "${1:-}" in
+  render) render ;;
+  store) store "${2:-}" ;;
+  *) die "usage: stellarstack-secrets render | store <file>" ;;
+esac
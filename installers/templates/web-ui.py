@@ -0,0 +1,132 @@
+# Browser front end for `install.sh web`. The installer writes each
+# prompt to <dir>/prompt and blocks reading <dir>/answer (a FIFO); this
+# server shows the prompt and the installer's output, and feeds the
+# answer back.
+#
+#   python3 web-ui.py <host> <port> <dir> <token>
+#
+# Every request must carry ?token=… (the installer prints the URL).
+
+import hmac
+import json
+import os
+import re
+import socket
+import sys
+import threading
+from http.server import BaseHTTPRequestHandler, ThreadingHTTPServer
+from urllib.parse import parse_qs, urlparse
+
+HOST, PORT, DIR, TOKEN = sys.argv[1], int(sys.argv[2]), sys.argv[3], sys.argv[4]
+ANSI = re.compile(r"\x1b\[[0-9;]*[A-Za-z]")
+LOG_TAIL = 64 * 1024
+answered = set()
+lock = threading.Lock()
+
+
+def read_prompt():
+    try:
+        with open(os.path.join(DIR, "prompt"), encoding="utf-8") as f:
+            lines = f.read().splitlines()
+    except FileNotFoundError:
+        return None
+    prompt = {"options": []}
+    for line in lines:
+        key, _, value = line.partition("\t")
+        if key == "option":
+            prompt["options"].append(value)
+        else:
+            prompt[key] = value
+    return prompt
+
+
+def read_log():
+    try:
+        with open(os.path.join(DIR, "output.log"), "rb") as f:
+            f.seek(max(0, os.fstat(f.fileno()).st_size - LOG_TAIL))
+            return ANSI.sub("", f.read().decode("utf-8", "replace"))
+    except FileNotFoundError:
+        return ""
+
+
+def read_done():
+    try:
+        with open(os.path.join(DIR, "done"), encoding="utf-8") as f:
+            return int(f.read().strip() or 0)
+    except FileNotFoundError:
+        return None
+
+
+class Handler(BaseHTTPRequestHandler):
+    def authorized(self):
+        token = parse_qs(urlparse(self.path).query).get("token", [""])[0]
+        if hmac.compare_digest(token, TOKEN):
+            return True
+        self.send(403, "text/plain", b"Bad or missing token.\n")
+        return False
+
+    def send(self, code, kind, body):
+        self.send_response(code)
+        self.send_header("Content-Type", kind)
+        self.send_header("Content-Length", str(len(body)))
+        self.send_header("Cache-Control", "no-store")
+        self.end_headers()
+        self.wfile.write(body)
+
+    def do_GET(self):
+        if not self.authorized():
+            return
+        path = urlparse(self.path).path
+        if path == "/":
+            with open(os.path.join(DIR, "web-ui.html"), "rb") as f:
+                self.send(200, "text/html; charset=utf-8", f.read())
+        elif path == "/state":
+            prompt = read_prompt()
+            if prompt and prompt.get("seq") in answered:
+                prompt = None
+            state = {"prompt": prompt, "log": read_log(), "done": read_done()}
+            self.send(200, "application/json", json.dumps(state).encode())
+        else:
+            self.send(404, "text/plain", b"Not found.\n")
+
+    def do_POST(self):
+        if not self.authorized():
+            return
+        if urlparse(self.path).path != "/answer":
+            self.send(404, "text/plain", b"Not found.\n")
+            return
+        length = int(self.headers.get("Content-Length") or 0)
+        try:
+            answer = json.loads(self.rfile.read(length) or b"{}")
+        except ValueError:
+            self.send(400, "text/plain", b"Bad JSON.\n")
+            return
+        seq = str(answer.get("seq", ""))
+        with lock:
+            prompt = read_prompt()
+            if not prompt or prompt.get("seq") != seq or seq in answered:
+                self.send(409, "text/plain", b"That prompt was already answered.\n")
+                return
+            answered.add(seq)
+        status = "cancel" if answer.get("cancel") else "ok"
+        value = answer.get("value", "")
+        if isinstance(value, list):
+            value = "\n".join(value)
+        # Blocks until the installer reads it; it's waiting already.
+        with open(os.path.join(DIR, "answer"), "w", encoding="utf-8") as f:
+            f.write(status + "\n" + str(value))
+        self.send(204, "text/plain", b"")
+
+    def log_message(self, *args):
+        pass
+
+
+class Server(ThreadingHTTPServer):
+    # ThreadingHTTPServer only binds IPv4; install.sh passes IPv6 hosts
+    # without their brackets.
+    address_family = socket.AF_INET6 if ":" in HOST else socket.AF_INET
+
+
+server = Server((HOST, PORT), Handler)
+open(os.path.join(DIR, "ready"), "w").close()
+server.serve_forever()
@@ -0,0 +1,77 @@
+#!/usr/bin/env bash
+# A full install, answered from a file, through the recording runner
+# (--dry-run): it has to get to the end without touching the host, and
+# commands.log has to hold what a real install would have run.
+source "$(dirname "$0")/helpers.sh"
+
+export TMPDIR="$SCRATCH"
+
+# What the run learns from this host and the network is pinned, so the
+# test gives the same answers on a laptop and in CI. Everything else is
+# the real install code.
+require_root() { :; }
+check_connectivity() { :; }
+has_ipv4_route() { :; }
+ensure_gum() { :; }
+run_system_checks() { :; }
+check_registry_speed() { :; }
+check_hostname_collision() { :; }
+verify_domain() { :; }
+detect_cloud() { :; }
+check_reverse_dns() { :; }
+check_email_domain() { :; }
+detect_public_ip() { echo 203.0.113.9; }
+check_nat() { :; }
+pick_bind_address() { :; }
+port_free() { :; }
+host_memory_mb() { echo 4096; }
+detect_firewall() { echo ufw; }
+firewall_port_open() { return 1; }
+installer_dir() { echo "$TESTS_DIR/.."; }
+docker() {
+  case "$1" in
+    info|--version) echo "Docker version 27.0.0," ;;
+  esac
+}
+curl() { return 1; }
+
+cat >"$SCRATCH/answers.tsv" <<'ANSWERS'
+Panel hostname	panel.example.com
+Admin email	admin@example.com
+Admin display name	Admin
+Admin password	correct-horse-battery
+Grafana admin password	
+Timezone	Europe/Berlin
+Data directory	/var/lib/stellarstack
+Enable monitoring	yes
+Tune Docker	no
+benchmark	no
+hardening	no
+ANSWERS
+
+host_config=present
+[[ -e /etc/stellarstack ]] || host_config=absent
+output=$(main full --dry-run --answers "$SCRATCH/answers.tsv" 2>&1)
+rc=$?
+check "the dry run finishes" [ "$rc" == 0 ]
+(( rc == 0 )) || printf '%s\n' "$output" | tail -n 30
+
+root=$(find "$SCRATCH" -maxdepth 1 -name 'stellarstack-dry-run.*' | head -n1)
+log="$root/commands.log"
+config="$root/etc/stellarstack"
+check "commands.log was written" [ -s "$log" ]
+check "images are pulled" grep -q "'docker' 'compose' 'pull'" "$log"
+check "the stack is started" grep -q "'docker' 'compose' 'up' '-d'$" "$log"
+check "migrations run" grep -q "'docker' 'compose' 'run' '--rm' 'api' 'node' './scripts/migrate.js'" "$log"
+check "HTTP is opened on ufw" grep -q "'ufw' 'allow' '80/tcp'" "$log"
+check "HTTPS is opened on ufw" grep -q "'ufw' 'allow' '443/tcp'" "$log"
+check "the compose file is generated under the dry-run root" [ -s "$config/docker-compose.yml" ]
+check "the Caddyfile serves the answered hostname" grep -q '^panel.example.com {' "$config/Caddyfile"
+check "the admin email reaches Caddy" grep -q 'admin@example.com' "$config/Caddyfile"
+check "monitoring is switched on" grep -q '^COMPOSE_PROFILES=.*monitoring' "$config/.env"
+check "the timezone is applied" grep -q 'TZ: "Europe/Berlin"' "$config/docker-compose.yml"
+if [[ "$host_config" == "absent" ]]; then
+  check "nothing is written to the real config dir" [ ! -e /etc/stellarstack ]
+fi
+
+finish
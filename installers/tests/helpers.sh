@@ -0,0 +1,26 @@
+# Sourced by the tests: loads install.sh's functions without running it,
+# and gives each test a scratch directory and a way to fail.
+
+TESTS_DIR=$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)
+# shellcheck source=../install.sh
+source "$TESTS_DIR/../install.sh"
+set +e
+
+SCRATCH=$(mktemp -d "${TMPDIR:-/tmp}/stellarstack-test.XXXXXX")
+trap 'rm -rf "$SCRATCH"' EXIT
+FAILURES=0
+
+# check <description> <command…> — runs the command, reports the result.
+check() {
+  local what="$1"; shift
+  if "$@"; then
+    printf '  ok    %s\n' "$what"
+  else
+    printf '  FAIL  %s\n' "$what"
+    FAILURES=$(( FAILURES + 1 ))
+  fi
+}
+
+finish() {
+  (( FAILURES == 0 )) || { printf '%d check(s) failed\n' "$FAILURES"; exit 1; }
+}
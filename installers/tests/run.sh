@@ -0,0 +1,14 @@
+#!/usr/bin/env bash
+# Runs every installer test; exits non-zero when any of them fails.
+#
+#   bash installers/tests/run.sh
+set -uo pipefail
+
+cd "$(dirname "$0")" || exit 1
+failed=()
+for test in *.sh; do
+  [[ "$test" != "run.sh" && "$test" != "helpers.sh" ]] || continue
+  printf '%s\n' "$test"
+  bash "$test" || failed+=("$test")
+done
+(( ${#failed[@]} == 0 )) || { printf 'Failed: %s\n' "${failed[*]}"; exit 1; }
@@ -0,0 +1,27 @@
+#!/usr/bin/env bash
+# parse_stun_response against Binding Success responses as stun_public_ip
+# hands them over: od's hex bytes, space-separated.
+source "$(dirname "$0")/helpers.sh"
+
+# The attribute layout coturn answers with: MAPPED-ADDRESS before
+# XOR-MAPPED-ADDRESS, then RESPONSE-ORIGIN, SOFTWARE and FINGERPRINT. The
+# mapped address is 198.51.100.23:41641.
+coturn=" 01 01 00 44 21 12 a4 42 4a 6f 8e 21 b3 c0 5d 7e 91 a2 f3 04
+ 00 01 00 08 00 01 a2 a9 c6 33 64 17 00 20 00 08 00 01 83 bb e7 21 c0 55
+ 80 2b 00 08 00 01 0d 96 cb 00 71 01 80 22 00 14 43 6f 74 75 72 6e 2d 34
+ 2e 36 2e 32 20 27 47 6f 72 73 74 27 80 28 00 04 36 ee 4f fc"
+# Only an IPv6 XOR-MAPPED-ADDRESS (2001:db8::1).
+ipv6_only=" 01 01 00 18 21 12 a4 42 4a 6f 8e 21 b3 c0 5d 7e 91 a2 f3 04
+ 00 20 00 14 00 02 83 bb 01 13 a9 fa 4a 6f 8e 21 b3 c0 5d 7e 91 a2 f3 05"
+
+flat() { tr -s ' \n' ' ' <<<"$1"; }
+no_address() { ! parse_stun_response "$(flat "$1")" >/dev/null; }
+
+check "the XOR-mapped IPv4 address is decoded" \
+  [ "$(parse_stun_response "$(flat "$coturn")")" == "198.51.100.23" ]
+check "an IPv6-only answer yields nothing" \
+  no_address "$ipv6_only"
+check "a bare header yields nothing" \
+  no_address "${coturn:0:61}"
+
+finish
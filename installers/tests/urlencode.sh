@@ -0,0 +1,29 @@
+#!/usr/bin/env bash
+# DB passwords with URL delimiters in them must survive the trip through
+# urlencode into DATABASE_URL, and stay literal in POSTGRES_PASSWORD.
+source "$(dirname "$0")/helpers.sh"
+
+# The URL as the API's driver reads it: userinfo split on ':' and
+# percent-decoded.
+url_credentials() {
+  python3 -c 'import sys, urllib.parse as u
+p = u.urlsplit(sys.argv[1])
+print(u.unquote(p.username), u.unquote(p.password), p.hostname, p.port, p.path, sep="\n")' "$1"
+}
+
+JOURNAL_DIR="$SCRATCH/journal"
+for password in 'p@ss' 'a:b' 'a/b' '100%' 'x#y' 'why?' 'all@:/%#?of them' 'naïve&=+$,;'; do
+  env_path="$SCRATCH/$RANDOM/.env"
+  POSTGRES_USER='us@er:1' POSTGRES_PASSWORD="$password" write_env_once "$env_path" https://panel.example.com >/dev/null
+  url=$(sed -n 's/^DATABASE_URL=//p' "$env_path")
+  check "$password: DATABASE_URL parses back to the credentials" \
+    [ "$(url_credentials "$url")" == "us@er:1"$'\n'"$password"$'\n'"postgres"$'\n'"5432"$'\n'"/stellarstack" ]
+  check "$password: POSTGRES_PASSWORD is kept literally" \
+    grep -qxF "POSTGRES_PASSWORD='$password'" "$env_path"
+done
+
+check "unreserved characters pass through" [ "$(urlencode 'aZ09.~_-')" == 'aZ09.~_-' ]
+check "delimiters are percent-encoded" [ "$(urlencode '@:/%#?')" == '%40%3A%2F%25%23%3F' ]
+check "multi-byte characters are encoded per byte" [ "$(urlencode 'ï')" == '%C3%AF' ]
+
+finish
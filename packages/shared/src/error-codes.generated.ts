@@ -25,6 +25,7 @@ export type ErrorCode =
   | "instances.nested_not_allowed"
   | "instances.pool_exhausted"
   | "internal.unexpected"
+  | "nodes.allocations.conflict"
   | "nodes.has_servers"
   | "nodes.not_found"
   | "nodes.pair.token_already_claimed"
@@ -97,6 +98,7 @@ export const errorCodes = [
   "instances.nested_not_allowed",
   "instances.pool_exhausted",
   "internal.unexpected",
+  "nodes.allocations.conflict",
   "nodes.has_servers",
   "nodes.not_found",
   "nodes.pair.token_already_claimed",